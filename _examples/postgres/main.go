@@ -41,7 +41,7 @@ func main() {
 	defer cancel()
 
 	// run migrations
-	if err = gomigrate.Migrate(ctx, db, postgres.New, migrations,
+	if err = gomigrate.Migrate(ctx, db, postgres.New, gomigrate.NewFS(migrations),
 		gomigrate.WithDirectory("migrations"), // set directory for migrations
 		gomigrate.WithTableName("gomigrate"),  // set custom table name for migrations
 		gomigrate.WithLogger(slog.Default()),  // set custom logger
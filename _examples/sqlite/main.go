@@ -30,7 +30,7 @@ func main() {
 	defer cancel()
 
 	// run migrations
-	if err = gomigrate.Migrate(ctx, db, sqlite.New, migrations,
+	if err = gomigrate.Migrate(ctx, db, sqlite.New, gomigrate.NewFS(migrations),
 		gomigrate.WithDirectory("migrations"), // set directory for migrations
 		gomigrate.WithTableName("gomigrate"),  // set custom table name for migrations
 		gomigrate.WithLogger(slog.Default()),  // set custom logger
@@ -6,18 +6,36 @@ import (
 
 func defaultConfig() *config {
 	return &config{
-		Directory: defaultDirectory,
-		TableName: defaultTableName,
-		Logger:    slog.Default(),
+		Directory:    defaultDirectory,
+		TableName:    defaultTableName,
+		Logger:       slog.Default(),
+		ChecksumMode: ChecksumWarn,
 	}
 }
 
 type config struct {
-	Directory string
-	TableName string
-	Logger    *slog.Logger
+	Directory    string
+	TableName    string
+	Logger       *slog.Logger
+	ChecksumMode ChecksumMode
+	Hooks        []Hook
 }
 
+// ChecksumMode controls how gomigrate reacts when an already-applied migration's file contents have
+// drifted from the checksum that was recorded when it was applied.
+type ChecksumMode int
+
+const (
+	// ChecksumWarn logs a warning through the configured logger on a checksum mismatch but continues migrating.
+	ChecksumWarn ChecksumMode = iota
+
+	// ChecksumStrict returns an error on a checksum mismatch instead of continuing.
+	ChecksumStrict
+
+	// ChecksumOff disables checksum verification entirely.
+	ChecksumOff
+)
+
 func (c *config) apply(opts ...Option) {
 	for _, opt := range opts {
 		opt.apply(c)
@@ -54,3 +72,20 @@ func WithLogger(l *slog.Logger) Option {
 		c.Logger = l
 	})
 }
+
+// WithChecksumMode sets how gomigrate reacts when an already-applied migration's file contents have
+// drifted from the checksum that was recorded when it was applied. It defaults to ChecksumWarn.
+func WithChecksumMode(mode ChecksumMode) Option {
+	return optionFunc(func(c *config) {
+		c.ChecksumMode = mode
+	})
+}
+
+// WithHook registers a hook that is called at each phase of the migration lifecycle. Hooks are
+// called in registration order; if a hook returns an error, the remaining hooks for that event
+// are skipped. Multiple hooks can be registered by passing WithHook more than once.
+func WithHook(hook Hook) Option {
+	return optionFunc(func(c *config) {
+		c.Hooks = append(c.Hooks, hook)
+	})
+}
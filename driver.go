@@ -3,19 +3,60 @@ package gomigrate
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 // NewDriver is a function that returns a new Driver.
 type NewDriver func(db Queryer, tableName string) Driver
 
+// AppliedVersion describes a schema version that has already been applied, when, and the checksum of
+// the migration file it was applied from. Checksum is empty for versions applied before gomigrate
+// tracked checksums, or for migrations with no file contents to hash, such as Go function migrations.
+type AppliedVersion struct {
+	Version   int
+	AppliedAt time.Time
+	Checksum  string
+}
+
 // Driver allows gomigrate to work with different databases since.
 type Driver interface {
+	// Name returns the name of the driver.
+	Name() string
+
 	// CreateVersionTable creates the versioning table if it does not exist.
 	CreateVersionTable(ctx context.Context) error
 
 	// GetVersion returns the most recent schema version.
 	GetVersion(ctx context.Context) (int, error)
 
-	// AddVersion adds a new schema version to the versioning table.
-	AddVersion(ctx context.Context, tx *sql.Tx, version int) error
+	// AddVersion adds a new schema version to the versioning table, along with the checksum of the
+	// migration file it was applied from.
+	AddVersion(ctx context.Context, tx *sql.Tx, version int, checksum string) error
+
+	// AddVersionNoTx adds a new schema version to the versioning table outside of a transaction.
+	// It is called instead of AddVersion for migrations that start with a "-- gomigrate: NoTransaction"
+	// header comment, since those are executed directly against the database rather than inside a
+	// transaction that could also hold the version insert.
+	AddVersionNoTx(ctx context.Context, version int, checksum string) error
+
+	// RemoveVersion removes a schema version from the versioning table.
+	// It is called when a down migration is executed as part of a rollback.
+	RemoveVersion(ctx context.Context, tx *sql.Tx, version int) error
+
+	// GetAppliedVersions returns every schema version that has been applied so far, along with the
+	// time it was applied at.
+	GetAppliedVersions(ctx context.Context) ([]AppliedVersion, error)
+}
+
+// Locker is an optional interface a Driver can implement to serialize concurrent migrations.
+// When a Driver implements Locker, Migrate and MigrateTo acquire the lock before reading the
+// current schema version and release it once they return, so that if multiple processes call
+// Migrate at the same time, only one of them applies migrations while the others block until it
+// finishes and then observe the up-to-date schema version.
+type Locker interface {
+	// Lock acquires an exclusive lock, blocking until it becomes available.
+	Lock(ctx context.Context) error
+
+	// Unlock releases a lock previously acquired with Lock.
+	Unlock(ctx context.Context) error
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 
 	"github.com/topi314/gomigrate"
 )
@@ -22,6 +23,17 @@ func New(db gomigrate.Queryer, tableName string) gomigrate.Driver {
 type driver struct {
 	db        gomigrate.Queryer
 	tableName string
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// connPinner is implemented by *sql.DB. Lock uses it, when available, to pin a single physical
+// connection for the lifetime of Lock/Unlock, since pg_advisory_lock/pg_advisory_unlock are
+// session-scoped: handing ExecContext calls to a pool could otherwise acquire the lock on one
+// pooled connection and release it on another, which is a silent no-op that leaves the lock held.
+type connPinner interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
 }
 
 func (d *driver) Name() string {
@@ -29,7 +41,12 @@ func (d *driver) Name() string {
 }
 
 func (d *driver) CreateVersionTable(ctx context.Context) error {
-	_, err := d.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version INT PRIMARY KEY, date TIMESTAMP DEFAULT CURRENT_TIMESTAMP)", d.tableName))
+	if _, err := d.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version INT PRIMARY KEY, date TIMESTAMP DEFAULT CURRENT_TIMESTAMP)", d.tableName)); err != nil {
+		return err
+	}
+
+	// Self-migration: add the checksum column used for drift detection if it doesn't exist yet.
+	_, err := d.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS checksum TEXT", d.tableName))
 	return err
 }
 
@@ -52,10 +69,98 @@ func (d *driver) GetVersion(ctx context.Context) (int, error) {
 	return v, nil
 }
 
-func (d *driver) AddVersion(ctx context.Context, tx *sql.Tx, version int) error {
-	_, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version) VALUES ($1)", d.tableName), version)
+func (d *driver) AddVersion(ctx context.Context, tx *sql.Tx, version int, checksum string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version, checksum) VALUES ($1, $2)", d.tableName), version, checksum)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (d *driver) AddVersionNoTx(ctx context.Context, version int, checksum string) error {
+	_, err := d.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version, checksum) VALUES ($1, $2)", d.tableName), version, checksum)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *driver) RemoveVersion(ctx context.Context, tx *sql.Tx, version int) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE version = $1", d.tableName), version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *driver) GetAppliedVersions(ctx context.Context) ([]gomigrate.AppliedVersion, error) {
+	rows, err := d.db.QueryContext(ctx, fmt.Sprintf("SELECT version, date, checksum FROM %s ORDER BY version", d.tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []gomigrate.AppliedVersion
+	for rows.Next() {
+		var v gomigrate.AppliedVersion
+		var checksum sql.NullString
+		if err = rows.Scan(&v.Version, &v.AppliedAt, &checksum); err != nil {
+			return nil, err
+		}
+		v.Checksum = checksum.String
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// Lock acquires a session-level Postgres advisory lock keyed on a hash of the table name, blocking
+// until it is free. This lets multiple processes call gomigrate.Migrate concurrently without racing
+// on CreateVersionTable/AddVersion.
+//
+// If db was constructed with a pooled *sql.DB, Lock pins a single *sql.Conn for the lock so that the
+// matching Unlock releases it on the same session; otherwise a pool could serve Lock and Unlock from
+// different physical connections, leaving the advisory lock held with nothing left to release it.
+func (d *driver) Lock(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if pinner, ok := d.db.(connPinner); ok {
+		conn, err := pinner.Conn(ctx)
+		if err != nil {
+			return err
+		}
+
+		if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", d.tableName); err != nil {
+			_ = conn.Close()
+			return err
+		}
+
+		d.conn = conn
+		return nil
+	}
+
+	_, err := d.db.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", d.tableName)
+	return err
+}
+
+// Unlock releases the advisory lock acquired with Lock, on the same connection it was acquired on
+// if one was pinned.
+func (d *driver) Unlock(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn != nil {
+		conn := d.conn
+		d.conn = nil
+
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", d.tableName)
+		if closeErr := conn.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", d.tableName)
+	return err
+}
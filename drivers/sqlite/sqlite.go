@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/topi314/gomigrate"
+)
+
+// Name is the name of the SQLite driver.
+const Name = "sqlite"
+
+// New returns a new SQLite driver.
+func New(db gomigrate.Queryer, tableName string) gomigrate.Driver {
+	return &driver{
+		db:        db,
+		tableName: tableName,
+	}
+}
+
+// migrateMu is shared by every driver value, since gomigrate.Migrate creates a new driver on each call.
+var migrateMu sync.Mutex
+
+type driver struct {
+	db        gomigrate.Queryer
+	tableName string
+}
+
+func (d *driver) Name() string {
+	return Name
+}
+
+func (d *driver) CreateVersionTable(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version INTEGER PRIMARY KEY, date TIMESTAMP DEFAULT CURRENT_TIMESTAMP, checksum TEXT)", d.tableName))
+	return err
+}
+
+func (d *driver) GetVersion(ctx context.Context) (int, error) {
+	raws, err := d.db.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s ORDER BY version DESC LIMIT 1", d.tableName))
+	if err != nil {
+		return 0, err
+	}
+	defer raws.Close()
+
+	if !raws.Next() {
+		return 0, nil
+	}
+
+	var v int
+	if err = raws.Scan(&v); err != nil {
+		return 0, err
+	}
+
+	return v, nil
+}
+
+func (d *driver) AddVersion(ctx context.Context, tx *sql.Tx, version int, checksum string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version, checksum) VALUES (?, ?)", d.tableName), version, checksum)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *driver) AddVersionNoTx(ctx context.Context, version int, checksum string) error {
+	_, err := d.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version, checksum) VALUES (?, ?)", d.tableName), version, checksum)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *driver) RemoveVersion(ctx context.Context, tx *sql.Tx, version int) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE version = ?", d.tableName), version)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *driver) GetAppliedVersions(ctx context.Context) ([]gomigrate.AppliedVersion, error) {
+	rows, err := d.db.QueryContext(ctx, fmt.Sprintf("SELECT version, date, checksum FROM %s ORDER BY version", d.tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []gomigrate.AppliedVersion
+	for rows.Next() {
+		var v gomigrate.AppliedVersion
+		var checksum sql.NullString
+		if err = rows.Scan(&v.Version, &v.AppliedAt, &checksum); err != nil {
+			return nil, err
+		}
+		v.Checksum = checksum.String
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// Lock acquires an in-process mutex so that concurrent callers of gomigrate.Migrate within the same
+// process don't race on CreateVersionTable/AddVersion. Unlike the Postgres driver's advisory lock,
+// this does not coordinate across separate processes: SQLite has no session-scoped lock primitive to
+// hook into over a pooled connection, so cross-process migration safety is left to the caller (e.g.
+// ensuring only one process runs migrations against a given database file).
+func (d *driver) Lock(ctx context.Context) error {
+	migrateMu.Lock()
+	return nil
+}
+
+// Unlock releases the mutex acquired with Lock.
+func (d *driver) Unlock(ctx context.Context) error {
+	migrateMu.Unlock()
+	return nil
+}
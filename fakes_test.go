@@ -0,0 +1,114 @@
+package gomigrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path"
+)
+
+// fakeQueryer is a Queryer that is never actually invoked. It lets tests exercise code paths that
+// only need a non-nil db to satisfy Migrate/Status's ErrNoDatabase check, without a live connection.
+type fakeQueryer struct{}
+
+func (fakeQueryer) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return nil, errors.New("fakeQueryer: not implemented")
+}
+
+func (fakeQueryer) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return nil, errors.New("fakeQueryer: not implemented")
+}
+
+func (fakeQueryer) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return nil, errors.New("fakeQueryer: not implemented")
+}
+
+// fakeSource is an in-memory Source backed by a map of file name to contents, for tests that need a
+// Source without reading from disk.
+type fakeSource struct {
+	files map[string][]byte
+}
+
+func (s *fakeSource) ReadDir(dir string) ([]DirEntry, error) {
+	entries := make([]DirEntry, 0, len(s.files))
+	for name := range s.files {
+		entries = append(entries, fakeEntry(name))
+	}
+	return entries, nil
+}
+
+func (s *fakeSource) ReadFile(p string) ([]byte, error) {
+	data, ok := s.files[path.Base(p)]
+	if !ok {
+		return nil, errors.New("fakeSource: no such file: " + p)
+	}
+	return data, nil
+}
+
+type fakeEntry string
+
+func (e fakeEntry) Name() string {
+	return string(e)
+}
+
+func (e fakeEntry) IsDir() bool {
+	return false
+}
+
+// fakeDriver is an in-memory Driver for tests that don't need a real database. It also implements
+// Locker, recording the relative order CreateVersionTable/Lock/Unlock were called in, and records
+// the versions passed to AddVersion/AddVersionNoTx/RemoveVersion so tests can assert on what a
+// migration run did.
+type fakeDriver struct {
+	name    string
+	version int
+	applied []AppliedVersion
+
+	lockCalls []string
+
+	addedVersions     []int
+	addedNoTxVersions []int
+	removedVersions   []int
+}
+
+func (d *fakeDriver) Name() string {
+	return d.name
+}
+
+func (d *fakeDriver) CreateVersionTable(ctx context.Context) error {
+	d.lockCalls = append(d.lockCalls, "create-version-table")
+	return nil
+}
+
+func (d *fakeDriver) GetVersion(ctx context.Context) (int, error) {
+	return d.version, nil
+}
+
+func (d *fakeDriver) AddVersion(ctx context.Context, tx *sql.Tx, version int, checksum string) error {
+	d.addedVersions = append(d.addedVersions, version)
+	return nil
+}
+
+func (d *fakeDriver) AddVersionNoTx(ctx context.Context, version int, checksum string) error {
+	d.addedNoTxVersions = append(d.addedNoTxVersions, version)
+	return nil
+}
+
+func (d *fakeDriver) RemoveVersion(ctx context.Context, tx *sql.Tx, version int) error {
+	d.removedVersions = append(d.removedVersions, version)
+	return nil
+}
+
+func (d *fakeDriver) GetAppliedVersions(ctx context.Context) ([]AppliedVersion, error) {
+	return d.applied, nil
+}
+
+func (d *fakeDriver) Lock(ctx context.Context) error {
+	d.lockCalls = append(d.lockCalls, "lock")
+	return nil
+}
+
+func (d *fakeDriver) Unlock(ctx context.Context) error {
+	d.lockCalls = append(d.lockCalls, "unlock")
+	return nil
+}
@@ -0,0 +1,96 @@
+package gomigrate
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+)
+
+// fakeSQLDriver is a minimal database/sql/driver.Driver that accepts any statement and returns no
+// rows, so tests can exercise code that needs a real *sql.DB (transactions, BeginTx) without a real
+// database.
+type fakeSQLDriver struct{}
+
+func init() {
+	sql.Register("gomigrate-fake", fakeSQLDriver{})
+}
+
+func (fakeSQLDriver) Open(name string) (driver.Conn, error) {
+	return fakeSQLConn{}, nil
+}
+
+type fakeSQLConn struct{}
+
+func (fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeSQLStmt{}, nil
+}
+
+func (fakeSQLConn) Close() error {
+	return nil
+}
+
+func (fakeSQLConn) Begin() (driver.Tx, error) {
+	return fakeSQLTx{}, nil
+}
+
+type fakeSQLStmt struct{}
+
+func (fakeSQLStmt) Close() error {
+	return nil
+}
+
+func (fakeSQLStmt) NumInput() int {
+	return -1
+}
+
+func (fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return fakeSQLResult{}, nil
+}
+
+func (fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return fakeSQLRows{}, nil
+}
+
+type fakeSQLResult struct{}
+
+func (fakeSQLResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (fakeSQLResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
+
+type fakeSQLRows struct{}
+
+func (fakeSQLRows) Columns() []string {
+	return nil
+}
+
+func (fakeSQLRows) Close() error {
+	return nil
+}
+
+func (fakeSQLRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+type fakeSQLTx struct{}
+
+func (fakeSQLTx) Commit() error {
+	return nil
+}
+
+func (fakeSQLTx) Rollback() error {
+	return nil
+}
+
+// newFakeQueryer returns a real *sql.DB backed by fakeSQLDriver, so tests can exercise
+// BeginTx/ExecContext without a live database.
+func newFakeQueryer() Queryer {
+	db, err := sql.Open("gomigrate-fake", "")
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
@@ -0,0 +1,71 @@
+package gomigrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrSkip can be returned by a hook during the BeforeEach phase to skip a migration without
+// executing it. gomigrate still records it as applied, so it won't be retried on a later run.
+// When MigrateTo is rolling back, the skipped migration's version is removed instead, without
+// executing its down statements.
+var ErrSkip = errors.New("gomigrate: skip migration")
+
+// HookPhase identifies the point in the migration lifecycle a HookEvent was emitted for.
+type HookPhase int
+
+const (
+	// BeforeAll is emitted once before any migrations run.
+	BeforeAll HookPhase = iota
+
+	// BeforeEach is emitted before each migration runs. A hook returning ErrSkip skips the
+	// migration instead of executing it.
+	BeforeEach
+
+	// AfterEach is emitted after each migration runs successfully, or is skipped.
+	AfterEach
+
+	// AfterAll is emitted once after all migrations have run successfully.
+	AfterAll
+
+	// OnError is emitted when a migration fails, before its transaction is rolled back.
+	OnError
+)
+
+// HookEvent describes a single point in the migration lifecycle that a hook registered with
+// WithHook can observe.
+type HookEvent struct {
+	Phase HookPhase
+
+	// Migration is the migration the event concerns. It is the zero value for the BeforeAll and
+	// AfterAll phases, which aren't tied to a single migration.
+	Migration MigrationStatus
+
+	// Duration is how long the migration has been running when the event is emitted. It is zero
+	// for BeforeAll, BeforeEach and AfterAll, and for the AfterEach event of a skipped migration.
+	Duration time.Duration
+
+	// Err is the error that caused the OnError phase. It is nil for every other phase.
+	Err error
+
+	// Tx is the migration's in-flight transaction during the OnError phase, so a hook can inspect
+	// it before it is rolled back. It is nil for every other phase, and for migrations that opted
+	// out of running inside a transaction.
+	Tx *sql.Tx
+}
+
+// Hook is called by Migrate and MigrateTo at each phase of the migration lifecycle. Register one
+// with WithHook to plug in metrics, tracing, or notifications without forking gomigrate.
+type Hook func(ctx context.Context, event HookEvent) error
+
+// runHooks calls every registered hook with event in order, stopping and returning the first error.
+func runHooks(ctx context.Context, cfg *config, event HookEvent) error {
+	for _, hook := range cfg.Hooks {
+		if err := hook(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
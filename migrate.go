@@ -2,14 +2,16 @@ package gomigrate
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
-	"embed"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"path"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -18,6 +20,19 @@ const (
 	migrationFileExt         = ".sql"
 	migrationSeparator       = "_"
 	migrationDriverSeparator = "."
+
+	directionUp   = "up"
+	directionDown = "down"
+
+	migrateUpMarker   = "-- +migrate Up"
+	migrateDownMarker = "-- +migrate Down"
+
+	// noTransactionDirective opts a migration out of running inside a transaction, for statements
+	// that Postgres and other databases refuse to run inside one (e.g. CREATE INDEX CONCURRENTLY).
+	// It must be the first non-empty line of the file, above any "-- +migrate Up" marker.
+	noTransactionDirective = "-- gomigrate: NoTransaction"
+
+	statementSeparator = ";"
 )
 
 var (
@@ -26,6 +41,9 @@ var (
 
 	// ErrNoDriver is returned when no driver is provided.
 	ErrNoDriver = errors.New("no driver provided")
+
+	// ErrNoDownMigration is returned when a rollback is requested for a migration that has no down statements.
+	ErrNoDownMigration = errors.New("no down migration available")
 )
 
 func wrapMigrateErr(name string, fileName string, version int, err error) error {
@@ -78,11 +96,63 @@ type Queryer interface {
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 }
 
-// Migrate reads and executes SQL migrations from the embed.FS to bring the database schema up to date.
+// prepareMigrate runs the setup shared by Migrate and MigrateTo: initializing the driver, loading
+// migrations, acquiring the driver's lock (if it implements Locker), creating the version table, and
+// verifying checksums. The version table is created after the lock is acquired, rather than before,
+// so that concurrent first-boot callers don't race each other creating it.
+//
+// On success, the returned unlock func must be deferred by the caller; it releases the lock acquired
+// above and is a no-op if the driver doesn't implement Locker. On error, prepareMigrate has already
+// released any lock it acquired, and the returned unlock is nil.
+func prepareMigrate(ctx context.Context, db Queryer, newDriver NewDriver, src Source, cfg *config) (Driver, []migration, func(), error) {
+	// initialize the driver
+	driver := newDriver(db, cfg.TableName)
+
+	// Load migrations from the source and sort them by version.
+	migrations, err := loadMigrations(src, cfg.Directory, driver.Name())
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	// If there are no migrations, we should return an error.
+	if len(migrations) == 0 {
+		return nil, nil, nil, fmt.Errorf("no migrations found in %s", cfg.Directory)
+	}
+
+	// If the driver supports it, acquire a lock so that only one process runs migrations at a time;
+	// the others block here until it finishes and then observe the up-to-date schema version.
+	unlock := func() {}
+	if locker, ok := driver.(Locker); ok {
+		if err = locker.Lock(ctx); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		unlock = func() {
+			_ = locker.Unlock(ctx)
+		}
+	}
+
+	// create the version table if it does not exist.
+	if err = driver.CreateVersionTable(ctx); err != nil {
+		unlock()
+		return nil, nil, nil, fmt.Errorf("failed to create version table: %w", err)
+	}
+
+	// Refuse to continue if an already-applied migration's file contents have drifted from what was
+	// originally applied, unless checksum verification has been disabled.
+	if err = verifyChecksums(ctx, driver, migrations, src, cfg); err != nil {
+		unlock()
+		return nil, nil, nil, err
+	}
+
+	return driver, migrations, unlock, nil
+}
+
+// Migrate reads and executes SQL migrations from src to bring the database schema up to date.
 // It keeps track of the executed migrations in a table.
 // If the database schema is ahead of the migrations, it will return an error.
 // Each migration runs in a transaction. If the context is canceled, the transaction for the current migration will be rolled back and it will return an error.
-func Migrate(ctx context.Context, db Queryer, newDriver NewDriver, fs embed.FS, opts ...Option) error {
+// Migrations that start with a "-- gomigrate: NoTransaction" header are the exception and run outside a transaction.
+func Migrate(ctx context.Context, db Queryer, newDriver NewDriver, src Source, opts ...Option) error {
 	if db == nil {
 		return ErrNoDatabase
 	}
@@ -94,24 +164,11 @@ func Migrate(ctx context.Context, db Queryer, newDriver NewDriver, fs embed.FS,
 	cfg := defaultConfig()
 	cfg.apply(opts...)
 
-	// initialize the driver
-	driver := newDriver(db, cfg.TableName)
-
-	// Load migrations from the embed.FS and sort them by version.
-	migrations, err := loadMigrations(fs, cfg.Directory, driver.Name())
+	driver, migrations, unlock, err := prepareMigrate(ctx, db, newDriver, src, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to load migrations: %w", err)
-	}
-
-	// If there are no migrations, we should return an error.
-	if len(migrations) == 0 {
-		return fmt.Errorf("no migrations found in %s", cfg.Directory)
-	}
-
-	// create the version table if it does not exist.
-	if err = driver.CreateVersionTable(ctx); err != nil {
-		return fmt.Errorf("failed to create version table: %w", err)
+		return err
 	}
+	defer unlock()
 
 	// Get the most recent schema version.
 	currentVersion, err := driver.GetVersion(ctx)
@@ -130,6 +187,10 @@ func Migrate(ctx context.Context, db Queryer, newDriver NewDriver, fs embed.FS,
 		return fmt.Errorf("schema version is ahead of migrations: current=%d, latest=%d", currentVersion, lastMigration.version)
 	}
 
+	if err = runHooks(ctx, cfg, HookEvent{Phase: BeforeAll}); err != nil {
+		return err
+	}
+
 	// Execute migrations.
 	for _, m := range migrations {
 		// Skip migrations that have already been executed.
@@ -137,29 +198,184 @@ func Migrate(ctx context.Context, db Queryer, newDriver NewDriver, fs embed.FS,
 			continue
 		}
 
-		if err = execMigration(ctx, db, driver, m, fs); err != nil {
+		if err = runMigration(ctx, db, driver, m, src, cfg); err != nil {
 			return wrapMigrateErr(m.name, m.filePath, m.version, err)
 		}
 	}
 
-	return nil
+	return runHooks(ctx, cfg, HookEvent{Phase: AfterAll})
+}
+
+// MigrateTo reads and executes SQL migrations from src to bring the database schema to targetVersion.
+// If targetVersion is ahead of the current schema version, it behaves like [Migrate] and runs the missing up migrations.
+// If targetVersion is behind the current schema version, it runs the down migrations for every version above
+// targetVersion, in descending order, calling [Driver.RemoveVersion] for each one it rolls back.
+// Each migration runs in its own transaction, except up migrations that start with a "-- gomigrate: NoTransaction" header,
+// which run outside one. If the context is canceled, the transaction for the current migration will be rolled back and it will return an error.
+func MigrateTo(ctx context.Context, db Queryer, newDriver NewDriver, src Source, targetVersion int, opts ...Option) error {
+	if db == nil {
+		return ErrNoDatabase
+	}
+	if newDriver == nil {
+		return ErrNoDriver
+	}
+
+	// Apply options to the default configuration.
+	cfg := defaultConfig()
+	cfg.apply(opts...)
+
+	driver, migrations, unlock, err := prepareMigrate(ctx, db, newDriver, src, cfg)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Get the most recent schema version.
+	currentVersion, err := driver.GetVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current version: %w", err)
+	}
+
+	if targetVersion == currentVersion {
+		return nil
+	}
+
+	if err = runHooks(ctx, cfg, HookEvent{Phase: BeforeAll}); err != nil {
+		return err
+	}
+
+	if targetVersion > currentVersion {
+		for _, m := range migrations {
+			if m.version <= currentVersion || m.version > targetVersion {
+				continue
+			}
+
+			if err = runMigration(ctx, db, driver, m, src, cfg); err != nil {
+				return wrapMigrateErr(m.name, m.filePath, m.version, err)
+			}
+		}
+		return runHooks(ctx, cfg, HookEvent{Phase: AfterAll})
+	}
+
+	// Step down through the schema versions in descending order.
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.version > currentVersion || m.version <= targetVersion {
+			continue
+		}
+
+		if err = runDownMigration(ctx, db, driver, m, src, cfg); err != nil {
+			return wrapMigrateErr(m.name, m.filePath, m.version, err)
+		}
+	}
+
+	return runHooks(ctx, cfg, HookEvent{Phase: AfterAll})
+}
+
+// MigrationStatus describes a single migration found in a source and whether it has been applied yet.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	FilePath  string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Status returns the status of every migration found in src for the driver created by newDriver,
+// without applying any of them.
+func Status(ctx context.Context, db Queryer, newDriver NewDriver, src Source, opts ...Option) ([]MigrationStatus, error) {
+	if db == nil {
+		return nil, ErrNoDatabase
+	}
+	if newDriver == nil {
+		return nil, ErrNoDriver
+	}
+
+	// Apply options to the default configuration.
+	cfg := defaultConfig()
+	cfg.apply(opts...)
+
+	// initialize the driver
+	driver := newDriver(db, cfg.TableName)
+
+	// Load migrations from the source and sort them by version.
+	migrations, err := loadMigrations(src, cfg.Directory, driver.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	// create the version table if it does not exist.
+	if err = driver.CreateVersionTable(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create version table: %w", err)
+	}
+
+	applied, err := driver.GetAppliedVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied versions: %w", err)
+	}
+
+	appliedAt := make(map[int]time.Time, len(applied))
+	for _, a := range applied {
+		appliedAt[a.Version] = a.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, m := range migrations {
+		at, ok := appliedAt[m.version]
+		statuses[i] = MigrationStatus{
+			Version:   m.version,
+			Name:      m.name,
+			FilePath:  m.filePath,
+			Applied:   ok,
+			AppliedAt: at,
+		}
+	}
+
+	return statuses, nil
+}
+
+// Plan returns the migrations that Migrate would run for the driver created by newDriver, in the
+// order it would run them, without executing any of them.
+func Plan(ctx context.Context, db Queryer, newDriver NewDriver, src Source, opts ...Option) ([]MigrationStatus, error) {
+	statuses, err := Status(ctx, db, newDriver, src, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Migrate only runs migrations above the most recent applied version, so an unapplied migration
+	// below it is not pending.
+	var latestApplied int
+	for _, s := range statuses {
+		if s.Applied && s.Version > latestApplied {
+			latestApplied = s.Version
+		}
+	}
+
+	var pending []MigrationStatus
+	for _, s := range statuses {
+		if s.Version > latestApplied {
+			pending = append(pending, s)
+		}
+	}
+
+	return pending, nil
 }
 
 type migration struct {
-	name     string
-	version  int
-	driver   string
-	filePath string
+	name         string
+	version      int
+	driver       string
+	filePath     string
+	downFilePath string
 }
 
-func loadMigrations(fs embed.FS, dir string, driver string) ([]migration, error) {
-	entries, err := fs.ReadDir(dir)
+func loadMigrations(src Source, dir string, driver string) ([]migration, error) {
+	entries, err := src.ReadDir(dir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read migrations directory '%s': %w", dir, err)
 	}
 
-	var migrations []migration
-outer:
+	var parsed []*parsedMigration
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -179,6 +395,17 @@ outer:
 			continue
 		}
 
+		parsed = append(parsed, mig)
+	}
+
+	// First pass: register the up migrations (or direction-less ones for backwards compatibility).
+	var migrations []migration
+outer:
+	for _, mig := range parsed {
+		if mig.direction == directionDown {
+			continue
+		}
+
 		for i, m := range migrations {
 			if m.version == mig.version {
 				if m.driver == mig.driver {
@@ -186,13 +413,33 @@ outer:
 				}
 
 				if mig.driver == driver {
-					migrations[i] = *mig
+					migrations[i] = mig.migration
 				}
 				continue outer
 			}
 		}
 
-		migrations = append(migrations, *mig)
+		migrations = append(migrations, mig.migration)
+	}
+
+	// Second pass: pair up down migration files with their matching up migration.
+	for _, mig := range parsed {
+		if mig.direction != directionDown {
+			continue
+		}
+
+		var paired bool
+		for i, m := range migrations {
+			if m.version == mig.version {
+				migrations[i].downFilePath = mig.filePath
+				paired = true
+				break
+			}
+		}
+
+		if !paired {
+			return nil, fmt.Errorf("down migration %s has no matching up migration", mig.filePath)
+		}
 	}
 
 	slices.SortFunc(migrations, func(m1 migration, m2 migration) int {
@@ -202,60 +449,394 @@ outer:
 	return migrations, nil
 }
 
-func parseMigrationFileName(dir string, fileName string) (*migration, error) {
+// parsedMigration is the result of parsing a single migration file name, before it is paired up with its
+// counterpart (if any) and turned into a migration.
+type parsedMigration struct {
+	migration
+	direction string
+}
+
+func parseMigrationFileName(dir string, fileName string) (*parsedMigration, error) {
 	name, ok := strings.CutSuffix(fileName, migrationFileExt)
 	if !ok {
 		return nil, fmt.Errorf("invalid migration file extension: %s", fileName)
 	}
 
+	// The base name may be followed by up to two dot-separated suffixes, a driver name and/or a direction
+	// ("up"/"down"), in either order, e.g. "1_name.postgres.sql", "1_name.down.sql" or "1_name.postgres.down.sql".
+	segments := strings.Split(name, migrationDriverSeparator)
+	name = segments[0]
+
+	direction := directionUp
 	var driver string
-	parts := strings.SplitN(name, migrationDriverSeparator, 2)
-	if len(parts) > 1 {
-		driver = parts[1]
-		name = parts[0]
+	for _, suffix := range segments[1:] {
+		if suffix == directionUp || suffix == directionDown {
+			direction = suffix
+			continue
+		}
+		driver = suffix
 	}
 
-	parts = strings.SplitN(name, migrationSeparator, 2)
+	parts := strings.SplitN(name, migrationSeparator, 2)
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("invalid migration file name: %s", fileName)
 	}
 
-	name = strings.ReplaceAll(parts[1], "_", " ")
+	migName := strings.ReplaceAll(parts[1], "_", " ")
 
 	version, err := strconv.Atoi(parts[0])
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse migration version: %w", err)
 	}
 
-	return &migration{
-		name:     name,
-		version:  version,
-		driver:   driver,
-		filePath: path.Join(dir, fileName),
+	return &parsedMigration{
+		migration: migration{
+			name:     migName,
+			version:  version,
+			driver:   driver,
+			filePath: path.Join(dir, fileName),
+		},
+		direction: direction,
 	}, nil
 }
 
-func execMigration(ctx context.Context, db Queryer, driver Driver, m migration, fs embed.FS) error {
-	data, err := fs.ReadFile(m.filePath)
+// toMigrationStatus builds the MigrationStatus reported on a HookEvent for m. Applied and AppliedAt
+// aren't known at the point hooks fire around execution, so they are left at their zero values.
+func toMigrationStatus(m migration) MigrationStatus {
+	return MigrationStatus{
+		Version:  m.version,
+		Name:     m.name,
+		FilePath: m.filePath,
+	}
+}
+
+// runMigration wraps execMigration with the BeforeEach/AfterEach hook events, and handles a
+// BeforeEach hook returning ErrSkip by recording the migration as applied without executing it.
+func runMigration(ctx context.Context, db Queryer, driver Driver, m migration, src Source, cfg *config) error {
+	status := toMigrationStatus(m)
+
+	if err := runHooks(ctx, cfg, HookEvent{Phase: BeforeEach, Migration: status}); err != nil {
+		if !errors.Is(err, ErrSkip) {
+			return err
+		}
+
+		if err = markMigrationApplied(ctx, db, driver, m); err != nil {
+			return err
+		}
+
+		return runHooks(ctx, cfg, HookEvent{Phase: AfterEach, Migration: status})
+	}
+
+	start := time.Now()
+	if err := execMigration(ctx, db, driver, m, src, cfg); err != nil {
+		return err
+	}
+
+	return runHooks(ctx, cfg, HookEvent{Phase: AfterEach, Migration: status, Duration: time.Since(start)})
+}
+
+// runDownMigration wraps execDownMigration with the BeforeEach/AfterEach hook events, and handles a
+// BeforeEach hook returning ErrSkip by removing the migration's version without rolling it back.
+func runDownMigration(ctx context.Context, db Queryer, driver Driver, m migration, src Source, cfg *config) error {
+	status := toMigrationStatus(m)
+
+	if err := runHooks(ctx, cfg, HookEvent{Phase: BeforeEach, Migration: status}); err != nil {
+		if !errors.Is(err, ErrSkip) {
+			return err
+		}
+
+		if err = markMigrationRolledBack(ctx, db, driver, m); err != nil {
+			return err
+		}
+
+		return runHooks(ctx, cfg, HookEvent{Phase: AfterEach, Migration: status})
+	}
+
+	start := time.Now()
+	if err := execDownMigration(ctx, db, driver, m, src, cfg); err != nil {
+		return err
+	}
+
+	return runHooks(ctx, cfg, HookEvent{Phase: AfterEach, Migration: status, Duration: time.Since(start)})
+}
+
+// markMigrationApplied records a migration as applied without executing it. It is used when a
+// BeforeEach hook returns ErrSkip.
+func markMigrationApplied(ctx context.Context, db Queryer, driver Driver, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if err = driver.AddVersion(ctx, tx, m.version, ""); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to set version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// markMigrationRolledBack removes a migration's version without executing its down statements. It is
+// used when a BeforeEach hook returns ErrSkip while rolling back.
+func markMigrationRolledBack(ctx context.Context, db Queryer, driver Driver, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if err = driver.RemoveVersion(ctx, tx, m.version); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to remove version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func execMigration(ctx context.Context, db Queryer, driver Driver, m migration, src Source, cfg *config) error {
+	start := time.Now()
+
+	if fsrc, ok := src.(FuncSource); ok {
+		if fn, ok := fsrc.MigrationFunc(m.filePath); ok {
+			return execFuncMigration(ctx, db, driver, m, fn, cfg, start)
+		}
+	}
+
+	data, err := src.ReadFile(m.filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read migration file: %w", err)
 	}
 
+	up, _ := splitMigrationDirections(data)
+	checksum := checksumData(data)
+
+	if hasNoTransactionDirective(data) {
+		return execMigrationNoTx(ctx, db, driver, m, up, checksum, cfg, start)
+	}
+
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %w", err)
 	}
 
-	_, err = tx.ExecContext(ctx, string(data))
+	_, err = tx.ExecContext(ctx, up)
+	if err != nil {
+		_ = runHooks(ctx, cfg, HookEvent{Phase: OnError, Migration: toMigrationStatus(m), Duration: time.Since(start), Err: err, Tx: tx})
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to execute migration: %w", err)
+	}
+
+	if err = driver.AddVersion(ctx, tx, m.version, checksum); err != nil {
+		_ = runHooks(ctx, cfg, HookEvent{Phase: OnError, Migration: toMigrationStatus(m), Duration: time.Since(start), Err: err, Tx: tx})
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to set version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// execFuncMigration runs a migration backed by a Go function, such as one registered through
+// gomigrate/sources/gofunc, inside its own transaction. Go function migrations have no file
+// contents to checksum, so they are recorded with an empty checksum and excluded from drift checks.
+func execFuncMigration(ctx context.Context, db Queryer, driver Driver, m migration, fn func(*sql.Tx) error, cfg *config, start time.Time) error {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if err = fn(tx); err != nil {
+		_ = runHooks(ctx, cfg, HookEvent{Phase: OnError, Migration: toMigrationStatus(m), Duration: time.Since(start), Err: err, Tx: tx})
 		_ = tx.Rollback()
 		return fmt.Errorf("failed to execute migration: %w", err)
 	}
 
-	if err = driver.AddVersion(ctx, tx, m.version); err != nil {
+	if err = driver.AddVersion(ctx, tx, m.version, ""); err != nil {
+		_ = runHooks(ctx, cfg, HookEvent{Phase: OnError, Migration: toMigrationStatus(m), Duration: time.Since(start), Err: err, Tx: tx})
 		_ = tx.Rollback()
 		return fmt.Errorf("failed to set version: %w", err)
 	}
 
 	return tx.Commit()
 }
+
+// execMigrationNoTx runs a migration outside of a transaction, for migrations that opted out of one with a
+// "-- gomigrate: NoTransaction" header comment. The statements are split on ";" boundaries and executed one
+// by one, so that a single failing statement doesn't leave the driver unable to continue on the next run.
+func execMigrationNoTx(ctx context.Context, db Queryer, driver Driver, m migration, up string, checksum string, cfg *config, start time.Time) error {
+	for _, stmt := range splitStatements(up) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			_ = runHooks(ctx, cfg, HookEvent{Phase: OnError, Migration: toMigrationStatus(m), Duration: time.Since(start), Err: err})
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
+	if err := driver.AddVersionNoTx(ctx, m.version, checksum); err != nil {
+		_ = runHooks(ctx, cfg, HookEvent{Phase: OnError, Migration: toMigrationStatus(m), Duration: time.Since(start), Err: err})
+		return fmt.Errorf("failed to set version: %w", err)
+	}
+
+	return nil
+}
+
+// hasNoTransactionDirective reports whether the first non-empty line of a migration file is the
+// "-- gomigrate: NoTransaction" header comment. It is checked against the raw file contents rather
+// than the already-split up statements, since the directive's natural placement is above the
+// "-- +migrate Up" marker, which splitMigrationDirections strips out.
+func hasNoTransactionDirective(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		return line == noTransactionDirective
+	}
+	return false
+}
+
+// splitStatements splits a block of SQL into individual statements on ";" boundaries, dropping empty ones.
+func splitStatements(sql string) []string {
+	var stmts []string
+	for _, stmt := range strings.Split(sql, statementSeparator) {
+		stmt = strings.TrimSpace(stmt)
+		if stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// execDownMigration rolls back a single migration by executing its down statements and removing its version
+// from the version table, all within a single transaction.
+//
+// The down statements are read from the migration's dedicated down file if one was paired during loading,
+// otherwise they are read from the "-- +migrate Down" section of the up file.
+func execDownMigration(ctx context.Context, db Queryer, driver Driver, m migration, src Source, cfg *config) error {
+	start := time.Now()
+
+	var down string
+	if m.downFilePath != "" {
+		data, err := src.ReadFile(m.downFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read down migration file: %w", err)
+		}
+		down = string(data)
+	} else {
+		data, err := src.ReadFile(m.filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read migration file: %w", err)
+		}
+
+		_, down = splitMigrationDirections(data)
+		if down == "" {
+			return ErrNoDownMigration
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, down); err != nil {
+		_ = runHooks(ctx, cfg, HookEvent{Phase: OnError, Migration: toMigrationStatus(m), Duration: time.Since(start), Err: err, Tx: tx})
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to execute down migration: %w", err)
+	}
+
+	if err = driver.RemoveVersion(ctx, tx, m.version); err != nil {
+		_ = runHooks(ctx, cfg, HookEvent{Phase: OnError, Migration: toMigrationStatus(m), Duration: time.Since(start), Err: err, Tx: tx})
+		_ = tx.Rollback()
+		return fmt.Errorf("failed to remove version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// checksumData returns the hex-encoded SHA-256 checksum of a migration's file contents.
+func checksumData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyChecksums re-hashes every already-applied migration still present in migrations and compares it
+// against the checksum recorded when it was applied. Migrations applied before checksums were tracked
+// have an empty recorded checksum and are skipped, so existing databases can adopt checksums gradually.
+func verifyChecksums(ctx context.Context, driver Driver, migrations []migration, src Source, cfg *config) error {
+	if cfg.ChecksumMode == ChecksumOff {
+		return nil
+	}
+
+	applied, err := driver.GetAppliedVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get applied versions: %w", err)
+	}
+
+	recordedChecksums := make(map[int]string, len(applied))
+	for _, a := range applied {
+		if a.Checksum != "" {
+			recordedChecksums[a.Version] = a.Checksum
+		}
+	}
+
+	fsrc, hasFuncSource := src.(FuncSource)
+
+	for _, m := range migrations {
+		recorded, ok := recordedChecksums[m.version]
+		if !ok {
+			continue
+		}
+
+		// Go function migrations have no file contents to hash.
+		if hasFuncSource {
+			if _, isFunc := fsrc.MigrationFunc(m.filePath); isFunc {
+				continue
+			}
+		}
+
+		data, err := src.ReadFile(m.filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read migration file for checksum verification: %w", err)
+		}
+
+		if checksumData(data) == recorded {
+			continue
+		}
+
+		msg := fmt.Sprintf("checksum mismatch for migration %d (%s): the file has changed since it was applied", m.version, m.name)
+		if cfg.ChecksumMode == ChecksumStrict {
+			return errors.New(msg)
+		}
+
+		cfg.Logger.Warn(msg)
+	}
+
+	return nil
+}
+
+// splitMigrationDirections splits the contents of a migration file into its up and down statements using the
+// "-- +migrate Up" / "-- +migrate Down" marker comments. Files without either marker are treated as an up-only
+// migration, so up is returned as the whole file and down as an empty string.
+func splitMigrationDirections(data []byte) (up string, down string) {
+	content := string(data)
+
+	upIdx := strings.Index(content, migrateUpMarker)
+	downIdx := strings.Index(content, migrateDownMarker)
+
+	if upIdx < 0 && downIdx < 0 {
+		return content, ""
+	}
+
+	if upIdx >= 0 {
+		start := upIdx + len(migrateUpMarker)
+		end := len(content)
+		if downIdx > upIdx {
+			end = downIdx
+		}
+		up = strings.TrimSpace(content[start:end])
+	}
+
+	if downIdx >= 0 {
+		down = strings.TrimSpace(content[downIdx+len(migrateDownMarker):])
+	}
+
+	return up, down
+}
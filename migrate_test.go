@@ -1,16 +1,23 @@
 package gomigrate
 
 import (
+	"bytes"
+	"context"
 	"embed"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 //go:embed testdata
 var testMigrations embed.FS
 
 func TestLoadMigrations(t *testing.T) {
-	migrations, err := loadMigrations(testMigrations, "testdata", "postgres")
+	migrations, err := loadMigrations(NewFS(testMigrations), "testdata", "postgres")
 	if err != nil {
 		t.Fatalf("unexpected error: %s", err)
 	}
@@ -110,6 +117,28 @@ func TestParseMigrationFileName(t *testing.T) {
 			expected: nil,
 			err:      fmt.Errorf("invalid migration file extension: 5_add_column_to_table_and_index_3.sqll"),
 		},
+		{
+			dir:      "migrations",
+			fileName: "6_drop_column.down.sql",
+			expected: &migration{
+				name:     "drop column",
+				version:  6,
+				driver:   "",
+				filePath: "migrations/6_drop_column.down.sql",
+			},
+			err: nil,
+		},
+		{
+			dir:      "migrations",
+			fileName: "7_drop_index.postgres.down.sql",
+			expected: &migration{
+				name:     "drop index",
+				version:  7,
+				driver:   "postgres",
+				filePath: "migrations/7_drop_index.postgres.down.sql",
+			},
+			err: nil,
+		},
 	}
 
 	for i, d := range data {
@@ -148,3 +177,303 @@ func TestParseMigrationFileName(t *testing.T) {
 		})
 	}
 }
+
+func TestMigrateToEmptyMigrations(t *testing.T) {
+	src := &fakeSource{files: map[string][]byte{}}
+	driver := &fakeDriver{name: "fake"}
+	newDriver := func(db Queryer, tableName string) Driver { return driver }
+
+	err := MigrateTo(context.Background(), newFakeQueryer(), newDriver, src, 5, WithDirectory("migrations"))
+	if err == nil {
+		t.Fatal("expected an error, got none")
+	}
+}
+
+func TestMigrateToUp(t *testing.T) {
+	src := &fakeSource{files: map[string][]byte{
+		"1_initial.sql": []byte("-- +migrate Up\nCREATE TABLE a (id INT);\n-- +migrate Down\nDROP TABLE a;"),
+		"2_more.sql":    []byte("-- +migrate Up\nCREATE TABLE b (id INT);\n-- +migrate Down\nDROP TABLE b;"),
+	}}
+	driver := &fakeDriver{name: "fake", version: 0}
+	newDriver := func(db Queryer, tableName string) Driver { return driver }
+
+	if err := MigrateTo(context.Background(), newFakeQueryer(), newDriver, src, 2, WithDirectory("migrations")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !slices.Equal(driver.addedVersions, []int{1, 2}) {
+		t.Errorf("expected versions 1 then 2 to be added, got: %v", driver.addedVersions)
+	}
+
+	if len(driver.removedVersions) != 0 {
+		t.Errorf("expected no versions to be removed, got: %v", driver.removedVersions)
+	}
+}
+
+func TestMigrateToDown(t *testing.T) {
+	src := &fakeSource{files: map[string][]byte{
+		"1_initial.sql": []byte("-- +migrate Up\nCREATE TABLE a (id INT);\n-- +migrate Down\nDROP TABLE a;"),
+		"2_more.sql":    []byte("-- +migrate Up\nCREATE TABLE b (id INT);\n-- +migrate Down\nDROP TABLE b;"),
+	}}
+	driver := &fakeDriver{name: "fake", version: 2}
+	newDriver := func(db Queryer, tableName string) Driver { return driver }
+
+	if err := MigrateTo(context.Background(), newFakeQueryer(), newDriver, src, 0, WithDirectory("migrations")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !slices.Equal(driver.removedVersions, []int{2, 1}) {
+		t.Errorf("expected versions 2 then 1 to be rolled back, got: %v", driver.removedVersions)
+	}
+
+	if len(driver.addedVersions) != 0 {
+		t.Errorf("expected no versions to be added, got: %v", driver.addedVersions)
+	}
+}
+
+func TestMigrateNoTransactionDirective(t *testing.T) {
+	src := &fakeSource{files: map[string][]byte{
+		"1_concurrent_index.sql": []byte("-- gomigrate: NoTransaction\n-- +migrate Up\nCREATE INDEX CONCURRENTLY idx ON a (id);\n-- +migrate Down\nDROP INDEX idx;"),
+	}}
+	driver := &fakeDriver{name: "fake", version: 0}
+	newDriver := func(db Queryer, tableName string) Driver { return driver }
+
+	if err := Migrate(context.Background(), newFakeQueryer(), newDriver, src, WithDirectory("migrations")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !slices.Equal(driver.addedNoTxVersions, []int{1}) {
+		t.Errorf("expected version 1 to be added outside a transaction, got: %v", driver.addedNoTxVersions)
+	}
+
+	if len(driver.addedVersions) != 0 {
+		t.Errorf("expected the NoTransaction migration not to go through AddVersion, got: %v", driver.addedVersions)
+	}
+}
+
+func TestMigrateAcquiresAndReleasesLock(t *testing.T) {
+	src := &fakeSource{files: map[string][]byte{
+		"1_initial.sql": []byte("CREATE TABLE a (id INT);"),
+	}}
+
+	driver := &fakeDriver{name: "fake", version: 1}
+	newDriver := func(db Queryer, tableName string) Driver { return driver }
+
+	if err := Migrate(context.Background(), fakeQueryer{}, newDriver, src, WithDirectory("migrations")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !slices.Equal(driver.lockCalls, []string{"lock", "create-version-table", "unlock"}) {
+		t.Errorf("expected the version table to be created after the lock was acquired and before it was released, got: %v", driver.lockCalls)
+	}
+}
+
+func TestStatusAndPlan(t *testing.T) {
+	src := &fakeSource{files: map[string][]byte{
+		"1_initial.sql": []byte("CREATE TABLE a (id INT);"),
+		"2_more.sql":    []byte("CREATE TABLE b (id INT);"),
+	}}
+
+	appliedAt := time.Now()
+	driver := &fakeDriver{
+		name: "fake",
+		applied: []AppliedVersion{
+			{Version: 1, AppliedAt: appliedAt, Checksum: checksumData([]byte("CREATE TABLE a (id INT);"))},
+		},
+	}
+	newDriver := func(db Queryer, tableName string) Driver { return driver }
+
+	statuses, err := Status(context.Background(), fakeQueryer{}, newDriver, src, WithDirectory("migrations"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses, got: %d", len(statuses))
+	}
+
+	if !statuses[0].Applied || !statuses[0].AppliedAt.Equal(appliedAt) {
+		t.Errorf("expected version 1 to be applied at %v, got: %+v", appliedAt, statuses[0])
+	}
+
+	if statuses[1].Applied {
+		t.Errorf("expected version 2 to not be applied, got: %+v", statuses[1])
+	}
+
+	pending, err := Plan(context.Background(), fakeQueryer{}, newDriver, src, WithDirectory("migrations"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Fatalf("expected only version 2 pending, got: %+v", pending)
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	migrations := []migration{
+		{name: "initial", version: 1, filePath: "migrations/1_initial.sql"},
+	}
+	src := &fakeSource{files: map[string][]byte{
+		"1_initial.sql": []byte("CREATE TABLE a (id INT);"),
+	}}
+	driver := &fakeDriver{applied: []AppliedVersion{
+		{Version: 1, Checksum: checksumData([]byte("CREATE TABLE a (id INT) -- changed"))},
+	}}
+
+	cfg := defaultConfig()
+	cfg.ChecksumMode = ChecksumOff
+	if err := verifyChecksums(context.Background(), driver, migrations, src, cfg); err != nil {
+		t.Errorf("ChecksumOff: unexpected error: %s", err)
+	}
+
+	var logs bytes.Buffer
+	cfg.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+	cfg.ChecksumMode = ChecksumWarn
+	if err := verifyChecksums(context.Background(), driver, migrations, src, cfg); err != nil {
+		t.Errorf("ChecksumWarn: unexpected error: %s", err)
+	}
+	if !strings.Contains(logs.String(), "checksum mismatch") {
+		t.Errorf("ChecksumWarn: expected a checksum mismatch warning to be logged, got: %s", logs.String())
+	}
+
+	cfg.ChecksumMode = ChecksumStrict
+	if err := verifyChecksums(context.Background(), driver, migrations, src, cfg); err == nil {
+		t.Error("ChecksumStrict: expected an error on checksum mismatch, got none")
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	var calls []string
+	cfg := defaultConfig()
+	cfg.Hooks = []Hook{
+		func(ctx context.Context, event HookEvent) error {
+			calls = append(calls, "first")
+			return nil
+		},
+		func(ctx context.Context, event HookEvent) error {
+			calls = append(calls, "second")
+			return errors.New("boom")
+		},
+		func(ctx context.Context, event HookEvent) error {
+			calls = append(calls, "third")
+			return nil
+		},
+	}
+
+	err := runHooks(context.Background(), cfg, HookEvent{Phase: BeforeAll})
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("expected error: boom, got: %v", err)
+	}
+
+	if !slices.Equal(calls, []string{"first", "second"}) {
+		t.Errorf("expected hooks to stop after the first error, got: %v", calls)
+	}
+}
+
+func TestChecksumData(t *testing.T) {
+	a := checksumData([]byte("CREATE TABLE a (id INT);"))
+	b := checksumData([]byte("CREATE TABLE a (id INT);"))
+	c := checksumData([]byte("CREATE TABLE b (id INT);"))
+
+	if a != b {
+		t.Errorf("expected identical contents to produce the same checksum, got: %s != %s", a, b)
+	}
+
+	if a == c {
+		t.Errorf("expected different contents to produce different checksums, got: %s == %s", a, c)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	stmts := splitStatements("CREATE TABLE a (id INT);\n\nCREATE TABLE b (id INT);\n")
+
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 statements, got: %d", len(stmts))
+	}
+
+	if stmts[0] != "CREATE TABLE a (id INT)" {
+		t.Errorf("expected first statement: %q, got: %q", "CREATE TABLE a (id INT)", stmts[0])
+	}
+
+	if stmts[1] != "CREATE TABLE b (id INT)" {
+		t.Errorf("expected second statement: %q, got: %q", "CREATE TABLE b (id INT)", stmts[1])
+	}
+}
+
+func TestHasNoTransactionDirective(t *testing.T) {
+	data := []struct {
+		name     string
+		file     string
+		expected bool
+	}{
+		{
+			name:     "no directive",
+			file:     "CREATE TABLE a (id INT);",
+			expected: false,
+		},
+		{
+			name:     "directive on first line",
+			file:     "-- gomigrate: NoTransaction\nCREATE INDEX CONCURRENTLY idx ON a (id);",
+			expected: true,
+		},
+		{
+			name:     "directive not on first line",
+			file:     "CREATE TABLE a (id INT);\n-- gomigrate: NoTransaction",
+			expected: false,
+		},
+		{
+			name:     "directive above +migrate Up marker",
+			file:     "-- gomigrate: NoTransaction\n-- +migrate Up\nCREATE INDEX CONCURRENTLY idx ON a (id);\n-- +migrate Down\nDROP INDEX idx;",
+			expected: true,
+		},
+		{
+			name:     "directive below +migrate Up marker",
+			file:     "-- +migrate Up\n-- gomigrate: NoTransaction\nCREATE INDEX CONCURRENTLY idx ON a (id);\n-- +migrate Down\nDROP INDEX idx;",
+			expected: false,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			if got := hasNoTransactionDirective([]byte(d.file)); got != d.expected {
+				t.Errorf("expected: %v, got: %v", d.expected, got)
+			}
+		})
+	}
+}
+
+func TestSplitMigrationDirections(t *testing.T) {
+	data := []struct {
+		name         string
+		content      string
+		expectedUp   string
+		expectedDown string
+	}{
+		{
+			name:         "no markers",
+			content:      "CREATE TABLE users (id SERIAL PRIMARY KEY);",
+			expectedUp:   "CREATE TABLE users (id SERIAL PRIMARY KEY);",
+			expectedDown: "",
+		},
+		{
+			name:         "up and down markers",
+			content:      "-- +migrate Up\nCREATE TABLE users (id SERIAL PRIMARY KEY);\n-- +migrate Down\nDROP TABLE users;",
+			expectedUp:   "CREATE TABLE users (id SERIAL PRIMARY KEY);",
+			expectedDown: "DROP TABLE users;",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			up, down := splitMigrationDirections([]byte(d.content))
+			if up != d.expectedUp {
+				t.Errorf("expected up: %s, got: %s", d.expectedUp, up)
+			}
+
+			if down != d.expectedDown {
+				t.Errorf("expected down: %s, got: %s", d.expectedDown, down)
+			}
+		})
+	}
+}
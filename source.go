@@ -0,0 +1,66 @@
+package gomigrate
+
+import (
+	"database/sql"
+	"io/fs"
+)
+
+// DirEntry describes a single entry returned by Source.ReadDir.
+type DirEntry interface {
+	// Name returns the base name of the entry.
+	Name() string
+
+	// IsDir reports whether the entry describes a directory.
+	IsDir() bool
+}
+
+// Source provides migration files to gomigrate. FS adapts any fs.FS, such as an embed.FS or
+// os.DirFS, into a Source. Additional implementations are available under gomigrate/sources.
+type Source interface {
+	// ReadDir reads the named directory and returns its entries.
+	ReadDir(dir string) ([]DirEntry, error)
+
+	// ReadFile reads the named file and returns its contents.
+	ReadFile(path string) ([]byte, error)
+}
+
+// FS adapts an fs.FS into a Source.
+type FS struct {
+	fs.FS
+}
+
+// NewFS wraps fsys, which may be an embed.FS, os.DirFS, or any other fs.FS, into a Source.
+func NewFS(fsys fs.FS) FS {
+	return FS{FS: fsys}
+}
+
+// ReadDir reads the named directory and returns its entries.
+func (f FS) ReadDir(dir string) ([]DirEntry, error) {
+	entries, err := fs.ReadDir(f.FS, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirEntries := make([]DirEntry, len(entries))
+	for i, entry := range entries {
+		dirEntries[i] = entry
+	}
+
+	return dirEntries, nil
+}
+
+// ReadFile reads the named file and returns its contents.
+func (f FS) ReadFile(path string) ([]byte, error) {
+	return fs.ReadFile(f.FS, path)
+}
+
+// FuncSource is an optional interface a Source can implement for migrations that are backed by a Go
+// function instead of a SQL file, such as the one under gomigrate/sources/gofunc. execMigration checks
+// for it and, when MigrationFunc returns a function, calls it inside the migration's transaction
+// instead of executing the file contents as SQL.
+type FuncSource interface {
+	Source
+
+	// MigrationFunc returns the registered function for path, and whether one was found.
+	MigrationFunc(path string) (func(*sql.Tx) error, bool)
+}
@@ -0,0 +1,67 @@
+// Package gofunc provides a gomigrate.Source whose migrations are Go functions instead of SQL
+// files, for cases where SQL isn't expressive enough, such as a data backfill that needs to
+// read and decode rows.
+package gofunc
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/topi314/gomigrate"
+)
+
+// Source is a gomigrate.Source whose migrations are registered Go functions.
+type Source struct {
+	migrations map[string]func(*sql.Tx) error
+}
+
+// New returns an empty Source. Migrations are added to it with RegisterMigration.
+func New() *Source {
+	return &Source{migrations: make(map[string]func(*sql.Tx) error)}
+}
+
+// RegisterMigration registers up to run for version. The version and name are combined into a
+// synthetic file name so the migration sorts and loads like any other.
+func (s *Source) RegisterMigration(version int, name string, up func(*sql.Tx) error) {
+	fileName := fmt.Sprintf("%d_%s.sql", version, strings.ReplaceAll(name, " ", "_"))
+	s.migrations[fileName] = up
+}
+
+// ReadDir returns a synthetic entry for every registered migration. dir is ignored since
+// registered migrations aren't namespaced by directory.
+func (s *Source) ReadDir(dir string) ([]gomigrate.DirEntry, error) {
+	entries := make([]gomigrate.DirEntry, 0, len(s.migrations))
+	for fileName := range s.migrations {
+		entries = append(entries, entry(fileName))
+	}
+
+	return entries, nil
+}
+
+// ReadFile returns empty contents for a registered migration and an error for any other path.
+// gomigrate applies a registered migration through MigrationFunc instead of its contents, and a
+// rollback of one reads these empty contents and finds no down statements.
+func (s *Source) ReadFile(p string) ([]byte, error) {
+	if _, ok := s.migrations[path.Base(p)]; !ok {
+		return nil, fmt.Errorf("no migration registered for %s", p)
+	}
+	return nil, nil
+}
+
+// MigrationFunc returns the function registered for p, and whether one was found.
+func (s *Source) MigrationFunc(p string) (func(*sql.Tx) error, bool) {
+	up, ok := s.migrations[path.Base(p)]
+	return up, ok
+}
+
+type entry string
+
+func (e entry) Name() string {
+	return string(e)
+}
+
+func (e entry) IsDir() bool {
+	return false
+}
@@ -0,0 +1,73 @@
+package gofunc
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestRegisterMigrationAndReadDir(t *testing.T) {
+	s := New()
+	s.RegisterMigration(1, "create table", func(tx *sql.Tx) error { return nil })
+	s.RegisterMigration(2, "add column", func(tx *sql.Tx) error { return nil })
+
+	entries, err := s.ReadDir("migrations")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got: %d", len(entries))
+	}
+
+	names := map[string]bool{}
+	for _, e := range entries {
+		if e.IsDir() {
+			t.Errorf("expected entry %q to not be a directory", e.Name())
+		}
+		names[e.Name()] = true
+	}
+
+	if !names["1_create_table.sql"] || !names["2_add_column.sql"] {
+		t.Errorf("expected synthetic file names for both migrations, got: %v", names)
+	}
+}
+
+func TestMigrationFunc(t *testing.T) {
+	s := New()
+
+	called := false
+	s.RegisterMigration(1, "create table", func(tx *sql.Tx) error {
+		called = true
+		return nil
+	})
+
+	fn, ok := s.MigrationFunc("migrations/1_create_table.sql")
+	if !ok {
+		t.Fatal("expected a registered migration func to be found")
+	}
+
+	if err := fn(nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !called {
+		t.Error("expected the registered function to have been called")
+	}
+
+	if _, ok = s.MigrationFunc("migrations/2_missing.sql"); ok {
+		t.Error("expected no migration func to be found for an unregistered version")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	s := New()
+	s.RegisterMigration(1, "create table", func(tx *sql.Tx) error { return nil })
+
+	if _, err := s.ReadFile("migrations/1_create_table.sql"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if _, err := s.ReadFile("migrations/2_missing.sql"); err == nil {
+		t.Error("expected an error for an unregistered migration")
+	}
+}
@@ -0,0 +1,79 @@
+// Package http provides a gomigrate.Source that fetches migrations over HTTP.
+//
+// The source expects dir/index.json to contain a JSON array of the file names in that directory,
+// and fetches each migration with a GET request to baseURL joined with its path.
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+
+	"github.com/topi314/gomigrate"
+)
+
+// Source is a gomigrate.Source that fetches migrations over HTTP.
+type Source struct {
+	client  *http.Client
+	baseURL string
+}
+
+// New returns a new Source that fetches migrations from baseURL using client.
+// If client is nil, http.DefaultClient is used.
+func New(baseURL string, client *http.Client) *Source {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Source{client: client, baseURL: baseURL}
+}
+
+// ReadDir fetches dir/index.json and returns its entries.
+func (s *Source) ReadDir(dir string) ([]gomigrate.DirEntry, error) {
+	data, err := s.get(path.Join(dir, "index.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch index: %w", err)
+	}
+
+	var names []string
+	if err = json.Unmarshal(data, &names); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+
+	entries := make([]gomigrate.DirEntry, len(names))
+	for i, name := range names {
+		entries[i] = entry(name)
+	}
+
+	return entries, nil
+}
+
+// ReadFile fetches the migration file at p.
+func (s *Source) ReadFile(p string) ([]byte, error) {
+	return s.get(p)
+}
+
+func (s *Source) get(p string) ([]byte, error) {
+	resp, err := s.client.Get(s.baseURL + "/" + p)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code for %s: %d", p, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+type entry string
+
+func (e entry) Name() string {
+	return string(e)
+}
+
+func (e entry) IsDir() bool {
+	return false
+}
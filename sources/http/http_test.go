@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadDir(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/migrations/index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`["1_initial.sql", "2_more.sql"]`))
+	}))
+	defer server.Close()
+
+	s := New(server.URL, nil)
+
+	entries, err := s.ReadDir("migrations")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got: %d", len(entries))
+	}
+
+	if entries[0].Name() != "1_initial.sql" || entries[1].Name() != "2_more.sql" {
+		t.Errorf("expected entries in index order, got: %v", entries)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/migrations/1_initial.sql" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("CREATE TABLE a (id INT);"))
+	}))
+	defer server.Close()
+
+	s := New(server.URL, nil)
+
+	data, err := s.ReadFile("migrations/1_initial.sql")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if string(data) != "CREATE TABLE a (id INT);" {
+		t.Errorf("unexpected file contents: %s", data)
+	}
+
+	if _, err = s.ReadFile("migrations/missing.sql"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
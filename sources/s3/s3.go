@@ -0,0 +1,85 @@
+// Package s3 provides a gomigrate.Source that reads migrations from an S3 bucket.
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/topi314/gomigrate"
+)
+
+// api is the subset of *s3.Client's methods Source needs. It lets tests substitute a fake client.
+type api interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// Source is a gomigrate.Source that reads migrations from an S3 bucket.
+type Source struct {
+	client api
+	bucket string
+}
+
+// New returns a new Source that reads migrations from bucket using client.
+func New(client *s3.Client, bucket string) *Source {
+	return &Source{client: client, bucket: bucket}
+}
+
+// ReadDir lists the objects under dir in the bucket, paging through ListObjectsV2 until every
+// object has been collected.
+func (s *Source) ReadDir(dir string) ([]gomigrate.DirEntry, error) {
+	ctx := context.Background()
+
+	var entries []gomigrate.DirEntry
+	var continuationToken *string
+	for {
+		out, err := s.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(s.bucket),
+			Prefix:            aws.String(dir + "/"),
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+
+		for _, obj := range out.Contents {
+			entries = append(entries, entry(path.Base(aws.ToString(obj.Key))))
+		}
+
+		if !aws.ToBool(out.IsTruncated) {
+			break
+		}
+		continuationToken = out.NextContinuationToken
+	}
+
+	return entries, nil
+}
+
+// ReadFile downloads the object at p from the bucket.
+func (s *Source) ReadFile(p string) ([]byte, error) {
+	out, err := s.client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(p),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
+type entry string
+
+func (e entry) Name() string {
+	return string(e)
+}
+
+func (e entry) IsDir() bool {
+	return false
+}
@@ -0,0 +1,97 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeAPI is a fake api that serves ListObjectsV2 from pre-baked pages, so tests can exercise
+// ReadDir's pagination without a real S3 bucket.
+type fakeAPI struct {
+	pages   [][]string
+	objects map[string]string
+}
+
+func (f *fakeAPI) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	pageIndex := 0
+	if params.ContinuationToken != nil {
+		var err error
+		pageIndex, err = parsePageToken(*params.ContinuationToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	page := f.pages[pageIndex]
+	contents := make([]types.Object, len(page))
+	for i, key := range page {
+		contents[i] = types.Object{Key: aws.String(key)}
+	}
+
+	out := &s3.ListObjectsV2Output{Contents: contents}
+
+	if pageIndex < len(f.pages)-1 {
+		out.IsTruncated = aws.Bool(true)
+		out.NextContinuationToken = aws.String(pageToken(pageIndex + 1))
+	}
+
+	return out, nil
+}
+
+func (f *fakeAPI) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data := f.objects[aws.ToString(params.Key)]
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(data))}, nil
+}
+
+func pageToken(i int) string {
+	return string(rune('0' + i))
+}
+
+func parsePageToken(token string) (int, error) {
+	return int(token[0] - '0'), nil
+}
+
+func TestReadDirPaginatesThroughAllPages(t *testing.T) {
+	api := &fakeAPI{
+		pages: [][]string{
+			{"migrations/1_initial.sql"},
+			{"migrations/2_more.sql"},
+		},
+	}
+	src := &Source{client: api, bucket: "bucket"}
+
+	entries, err := src.ReadDir("migrations")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries across both pages, got: %d", len(entries))
+	}
+
+	if entries[0].Name() != "1_initial.sql" || entries[1].Name() != "2_more.sql" {
+		t.Errorf("expected one entry per page, got: %v", entries)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	api := &fakeAPI{objects: map[string]string{
+		"migrations/1_initial.sql": "CREATE TABLE a (id INT);",
+	}}
+	src := &Source{client: api, bucket: "bucket"}
+
+	data, err := src.ReadFile("migrations/1_initial.sql")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if string(data) != "CREATE TABLE a (id INT);" {
+		t.Errorf("unexpected file contents: %s", data)
+	}
+}